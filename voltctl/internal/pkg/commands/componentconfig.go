@@ -0,0 +1,714 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/opencord/voltctl/pkg/format"
+	"github.com/opencord/voltctl/pkg/model"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ConfigTypeOptions selects the config type a config command acts on
+type ConfigTypeOptions struct {
+	Type string `long:"type" short:"t" required:"yes" value-name:"CONFIG_TYPE" description:"Config type, see voltctl config types"`
+}
+
+// GetComponentConfigOpts represents the supported CLI arguments for the config get command
+type GetComponentConfigOpts struct {
+	KvStoreOptions
+	ConfigTypeOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Get the value set for a single instance (pod) of the component"`
+	Args     struct {
+		Component string
+		Key       string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// SetComponentConfigOpts represents the supported CLI arguments for the config set command
+type SetComponentConfigOpts struct {
+	OutputOptions
+	KvStoreOptions
+	ConfigTypeOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Set the value for a single instance (pod) of the component only"`
+	Args     struct {
+		Component string
+		Key       string
+		Value     string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// DeleteComponentConfigOpts represents the supported CLI arguments for the config delete command
+type DeleteComponentConfigOpts struct {
+	OutputOptions
+	KvStoreOptions
+	ConfigTypeOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Delete the value set for a single instance (pod) of the component"`
+	Args     struct {
+		Component string
+		Key       string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ListComponentConfigOpts represents the supported CLI arguments for the config list command
+type ListComponentConfigOpts struct {
+	ListOutputOptions
+	KvStoreOptions
+	ConfigTypeOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"List the config set for a single instance (pod) of the component"`
+	Args     struct {
+		Component []string
+	} `positional-args:"yes"`
+}
+
+// WatchComponentConfigOpts represents the supported CLI arguments for the config watch command
+type WatchComponentConfigOpts struct {
+	OutputOptions
+	KvStoreOptions
+	ConfigTypeOptions
+	Instance string        `long:"instance" value-name:"INSTANCE_ID" description:"Watch the config of a single instance (pod) of the component"`
+	Duration time.Duration `long:"duration" value-name:"DURATION" description:"Stop watching after the given time instead of when interrupted"`
+	Args     struct {
+		Component string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// BackupComponentConfigOpts represents the supported CLI arguments for the config backup command
+type BackupComponentConfigOpts struct {
+	KvStoreOptions
+}
+
+// RestoreComponentConfigOpts represents the supported CLI arguments for the config restore command
+type RestoreComponentConfigOpts struct {
+	KvStoreOptions
+	Prune    bool `long:"prune" description:"Delete the config entries that are not in the archive"`
+	Validate bool `long:"validate" description:"Refuse an archive with values that are invalid for their config type"`
+	DryRun   bool `long:"dry-run" description:"Only validate the archive, without connecting to the kvstore"`
+	Args     struct {
+		File string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ListConfigTypesOpts represents the supported CLI arguments for the config types command
+type ListConfigTypesOpts struct {
+	ListOutputOptions
+}
+
+// ComponentConfigOpts represents the config commands
+type ComponentConfigOpts struct {
+	GetComponentConfig    GetComponentConfigOpts     `command:"get"`
+	SetComponentConfig    SetComponentConfigOpts     `command:"set"`
+	DeleteComponentConfig DeleteComponentConfigOpts  `command:"delete"`
+	ListComponentConfig   ListComponentConfigOpts    `command:"list"`
+	WatchComponentConfig  WatchComponentConfigOpts   `command:"watch"`
+	ListConfigTypes       ListConfigTypesOpts        `command:"types"`
+	Backup                BackupComponentConfigOpts  `command:"backup"`
+	Restore               RestoreComponentConfigOpts `command:"restore"`
+}
+
+// ConfigTypeOutput represents the output structure for the config types command
+type ConfigTypeOutput struct {
+	Name     string
+	Defaults string
+}
+
+var componentConfigOpts = ComponentConfigOpts{}
+
+const (
+	DEFAULT_COMPONENT_CONFIG_FORMAT          = "table{{ .ComponentName }}\t{{.Key}}\t{{.Value}}"
+	DEFAULT_INSTANCE_COMPONENT_CONFIG_FORMAT = "table{{ .ComponentName }}\t{{.InstanceId}}\t{{.Key}}\t{{.Value}}"
+	DEFAULT_CONFIG_CHANGE_FORMAT             = "{{ .ComponentName }}\t{{.ChangeType}}\t{{.Key}}\t{{.Value}}"
+	DEFAULT_CONFIG_TYPES_FORMAT              = "table{{ .Name }}\t{{.Defaults}}"
+)
+
+// RegisterComponentConfigCommands is used to register get, set, delete, list, watch, backup and restore of
+// the config of components for any config type
+func RegisterComponentConfigCommands(parent *flags.Parser) {
+	_, err := parent.AddCommand("config", "component config commands", "get, set, delete, list, watch, backup and restore the config of components for any config type", &componentConfigOpts)
+	if err != nil {
+		Error.Fatalf("Unable to register component config commands with voltctl command parser: %s", err.Error())
+	}
+}
+
+// configType returns the config type selected with --type
+func (options *ConfigTypeOptions) configType() (config.ConfigType, error) {
+	return config.ParseConfigType(options.Type)
+}
+
+// processComponentNames validates the component names given in command arguments, the global
+// component if none is given. Names may be escaped as for loglevel, but can't refer to a package
+func processComponentNames(components []string, instanceId string) ([]string, error) {
+	if len(components) == 0 {
+		components = []string{defaultComponentName}
+	}
+
+	names := make([]string, len(components))
+	for i, component := range components {
+		componentName, _, hasPackage, err := splitComponentArg(component)
+		if err != nil {
+			return nil, err
+		}
+		if componentName == "" {
+			return nil, fmt.Errorf("Component name is missing in %q", component)
+		}
+		if hasPackage {
+			return nil, fmt.Errorf("Configuration is set per component, not per package, in %q", component)
+		}
+		if componentName == defaultComponentName && instanceId != "" {
+			return nil, errors.New("global configuration doesn't support instance scope, specify the component")
+		}
+		names[i] = componentName
+	}
+	return names, nil
+}
+
+// configTarget is a config key of a component, or of an instance of the component, updated by a command
+type configTarget struct {
+	componentName string
+	instanceId    string
+	key           string
+	// keyGiven is set when the key was named in the argument of the target rather than defaulted
+	keyGiven bool
+}
+
+// updateComponentConfigs calls update for every target concurrently, with the ComponentConfig of the target,
+// and prints the result of every target like loglevel set. It returns the ExitError of loglevel set, if any
+func updateComponentConfigs(options OutputOptions, kvOptions KvStoreOptions, commandName string, configType config.ConfigType,
+	targets []configTarget, update func(context.Context, *config.ComponentConfig, string) error) error {
+	componentNames := make([]string, len(targets))
+	for i, target := range targets {
+		componentNames[i] = target.componentName
+	}
+
+	cmOptions, err := kvOptions.configManagerOptions()
+	if err != nil {
+		return generateFailedOutput(options, commandName, componentNames, ExitCodeValidationFailure, err)
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return generateFailedOutput(options, commandName, componentNames, ExitCodeConnectionFailure, err)
+	}
+	defer client.Close()
+
+	output := make([]LogLevelOutput, len(targets))
+	forEachConcurrently(len(targets), defaultConfigWorkers, func(i int) {
+		target := targets[i]
+		componentConfig := cm.InitComponentConfig(target.componentName, configType).ForInstance(target.instanceId)
+
+		if err := update(ctx, componentConfig, target.key); err != nil {
+			output[i] = LogLevelOutput{ComponentName: target.componentName, Status: "Failure", Error: describeConfigError(err), ErrorCode: configErrorCode(err)}
+		} else {
+			output[i] = LogLevelOutput{ComponentName: target.componentName, Status: "Success"}
+		}
+	})
+	return generateResultOutput(options, commandName, output)
+}
+
+// retrieveComponentConfigs returns the entries of a config type by component name, for the given components
+// or for all components if components is nil. A non empty instanceId reads the entries of that instance
+func retrieveComponentConfigs(ctx context.Context, cm *config.ConfigManager, configType config.ConfigType, components []string, instanceId string) (map[string]map[string]string, error) {
+	if components == nil {
+		componentConfigs, err := cm.RetrieveAllComponents(ctx, configType)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve %s configuration of voltha components : %s ", configType, describeConfigError(err))
+		}
+		return componentConfigs, nil
+	}
+
+	configs := make([]map[string]string, len(components))
+	errs := make([]error, len(components))
+	forEachConcurrently(len(components), defaultConfigWorkers, func(i int) {
+		configs[i], errs[i] = cm.InitComponentConfig(components[i], configType).ForInstance(instanceId).RetrieveAll(ctx)
+	})
+
+	componentConfigs := make(map[string]map[string]string)
+	for i, componentName := range components {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("Unable to retrieve %s configuration for component %s : %s", configType, componentName, describeConfigError(errs[i]))
+		}
+		componentConfigs[componentName] = configs[i]
+	}
+	return componentConfigs, nil
+}
+
+// componentTargets returns the targets for a config key of the components
+func componentTargets(components []string, instanceId string, key string) []configTarget {
+	targets := make([]configTarget, len(components))
+	for i, componentName := range components {
+		targets[i] = configTarget{componentName: componentName, instanceId: instanceId, key: key}
+	}
+	return targets
+}
+
+// listedComponentNames validates the component names given to a list command, returning nil to list all
+// components if none is given. A single instance can only be listed for named components
+func listedComponentNames(components []string, instanceId string) ([]string, error) {
+	if len(components) == 0 {
+		if instanceId != "" {
+			return nil, errors.New("The component name is required with --instance")
+		}
+		return nil, nil
+	}
+	return processComponentNames(components, instanceId)
+}
+
+// saveComponentConfigs checks value against the schema of configType and saves it as key of the components
+// named in componentArgs, or of an instance of them, printing the result of every component
+func saveComponentConfigs(options OutputOptions, kvOptions KvStoreOptions, commandName string, configType config.ConfigType,
+	componentArgs []string, instanceId string, key string, value string) error {
+	value, err := configType.Validate(key, value)
+	if err != nil {
+		return generateFailedOutput(options, commandName, componentArgs, ExitCodeValidationFailure, err)
+	}
+	components, err := processComponentNames(componentArgs, instanceId)
+	if err != nil {
+		return generateFailedOutput(options, commandName, componentArgs, ExitCodeValidationFailure, err)
+	}
+
+	return updateComponentConfigs(options, kvOptions, commandName, configType, componentTargets(components, instanceId, key),
+		func(ctx context.Context, componentConfig *config.ComponentConfig, key string) error {
+			return componentConfig.Save(ctx, key, value)
+		})
+}
+
+// deleteComponentConfigs deletes key of the components named in componentArgs, or of an instance of them,
+// printing the result of every component. If keys is not nil, key has to be one of them
+func deleteComponentConfigs(options OutputOptions, kvOptions KvStoreOptions, commandName string, configType config.ConfigType,
+	keys []string, componentArgs []string, instanceId string, key string) error {
+	if keys != nil && !containsString(keys, key) {
+		return generateFailedOutput(options, commandName, componentArgs, ExitCodeValidationFailure,
+			fmt.Errorf("Unknown %s key %q, expected one of %s", configType, key, strings.Join(keys, ", ")))
+	}
+	components, err := processComponentNames(componentArgs, instanceId)
+	if err != nil {
+		return generateFailedOutput(options, commandName, componentArgs, ExitCodeValidationFailure, err)
+	}
+
+	return updateComponentConfigs(options, kvOptions, commandName, configType, componentTargets(components, instanceId, key),
+		func(ctx context.Context, componentConfig *config.ComponentConfig, key string) error {
+			return componentConfig.Delete(ctx, key)
+		})
+}
+
+// containsString checks whether s is one of values
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// configListing describes how a list command shows the entries of a config type
+type configListing struct {
+	commandName string
+	configType  config.ConfigType
+	// format and instanceFormat are the default output formats, instanceFormat when an instance is listed
+	format         string
+	instanceFormat string
+	// model is a zero value of the rows of the command, which sets the type of the listed data
+	model interface{}
+	// row returns the row of the model for an entry, or false to leave the entry out
+	row func(componentName string, instanceId string, key string, value string) (interface{}, bool)
+}
+
+// listComponentConfigs prints the entries of the config type of listing for the given components, or for all
+// components if components is nil, as rows of the model of listing. A non empty instanceId lists the entries
+// of that instance
+func listComponentConfigs(options ListOutputOptions, kvOptions KvStoreOptions, listing configListing, components []string, instanceId string) error {
+	cmOptions, err := kvOptions.configManagerOptions()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	componentConfigs, err := retrieveComponentConfigs(ctx, cm, listing.configType, components, instanceId)
+	if err != nil {
+		return err
+	}
+
+	data := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(listing.model)), 0, len(componentConfigs))
+	for componentName, entries := range componentConfigs {
+		for key, value := range entries {
+			if row, ok := listing.row(componentName, instanceId, key, value); ok {
+				data = reflect.Append(data, reflect.ValueOf(row))
+			}
+		}
+	}
+
+	outputFormat := CharReplacer.Replace(options.Format)
+	if outputFormat == "" {
+		defaultFormat := listing.format
+		if instanceId != "" {
+			defaultFormat = listing.instanceFormat
+		}
+		outputFormat = GetCommandOptionWithDefault(listing.commandName, "format", defaultFormat)
+	}
+	orderBy := options.OrderBy
+	if orderBy == "" {
+		orderBy = GetCommandOptionWithDefault(listing.commandName, "order", "a")
+	}
+
+	result := CommandResult{
+		Format:    format.Format(outputFormat),
+		Filter:    options.Filter,
+		OrderBy:   orderBy,
+		OutputAs:  toOutputType(options.OutputAs),
+		NameLimit: options.NameLimit,
+		Data:      data.Interface(),
+	}
+	GenerateOutput(&result)
+	return nil
+}
+
+// processComponentName validates a single component name given in command arguments
+func processComponentName(component string, instanceId string) (string, error) {
+	components, err := processComponentNames([]string{component}, instanceId)
+	if err != nil {
+		return "", err
+	}
+	return components[0], nil
+}
+
+// This method gets a single config value of a component and prints only the value, for use in scripts.
+// It fails if no value is stored for the key.
+// For example, using below command the tracing collector of a component can be get
+// voltctl config get --type tracing <componentName> collector
+func (options *GetComponentConfigOpts) Execute(args []string) error {
+	configType, err := options.configType()
+	if err != nil {
+		return err
+	}
+	componentName, err := processComponentName(options.Args.Component, options.Instance)
+	if err != nil {
+		return err
+	}
+
+	cmOptions, err := options.configManagerOptions()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	componentConfig := cm.InitComponentConfig(componentName, configType).ForInstance(options.Instance)
+	value, found, err := componentConfig.Retrieve(ctx, options.Args.Key)
+	if err != nil {
+		return fmt.Errorf("Unable to retrieve %s %s of component %s : %s", configType, options.Args.Key, componentName, describeConfigError(err))
+	}
+	if !found {
+		return fmt.Errorf("No %s %s is set for component %s", configType, options.Args.Key, componentName)
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+// This method sets a single config value of a component. The value is checked against the schema of the
+// config type before connecting to the kvstore, so a typo fails without touching the stored config.
+// For example, using below command the tracing sampling ratio of a component can be set
+// voltctl config set --type tracing <componentName> sampling-ratio 0.5
+// It exits with ExitCodeValidationFailure for an unknown config type or an invalid value
+func (options *SetComponentConfigOpts) Execute(args []string) error {
+	configType, err := options.configType()
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "config-set", []string{options.Args.Component}, ExitCodeValidationFailure, err)
+	}
+	return saveComponentConfigs(options.OutputOptions, options.KvStoreOptions, "config-set", configType,
+		[]string{options.Args.Component}, options.Instance, options.Args.Key, options.Args.Value)
+}
+
+// This method deletes a single config value of a component, which then falls back to the global value or
+// the default declared by the config type. Keys are not checked, so that keys of config types that are
+// no longer registered can be deleted too.
+// For example, using below command the kafka config key of a component can be deleted
+// voltctl config delete --type kafka <componentName> <key>
+func (options *DeleteComponentConfigOpts) Execute(args []string) error {
+	configType, err := options.configType()
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "config-delete", []string{options.Args.Component}, ExitCodeValidationFailure, err)
+	}
+	return deleteComponentConfigs(options.OutputOptions, options.KvStoreOptions, "config-delete", configType,
+		nil, []string{options.Args.Component}, options.Instance, options.Args.Key)
+}
+
+// This method lists the config of a config type set for components, with the config type in every row so
+// that the JSON and YAML output of several types can be merged.
+// For example, using below command the kafka config of all components can be list
+// voltctl config list --type kafka
+// For example, using below command the tracing config set for a single instance can be list for the component
+// voltctl config list --type tracing <componentName> --instance <podName>
+func (options *ListComponentConfigOpts) Execute(args []string) error {
+	configType, err := options.configType()
+	if err != nil {
+		return err
+	}
+	components, err := listedComponentNames(options.Args.Component, options.Instance)
+	if err != nil {
+		return err
+	}
+
+	return listComponentConfigs(options.ListOutputOptions, options.KvStoreOptions, configListing{
+		commandName:    "config-list",
+		configType:     configType,
+		format:         DEFAULT_COMPONENT_CONFIG_FORMAT,
+		instanceFormat: DEFAULT_INSTANCE_COMPONENT_CONFIG_FORMAT,
+		model:          model.ComponentConfig{},
+		row: func(componentName string, instanceId string, key string, value string) (interface{}, bool) {
+			componentConfig := model.ComponentConfig{InstanceId: instanceId}
+			componentConfig.PopulateFrom(componentName, configType.String(), key, value)
+			return componentConfig, true
+		},
+	}, components, options.Instance)
+}
+
+// This method prints the config changes of a component as they happen, until interrupted or, with --duration,
+// until the given time has passed. The value of a key is read back when it is put, so a value changed again
+// in the meantime is printed with its latest value.
+// For example, using below command the loglevel changes of a component can be watched
+// voltctl config watch --type loglevel <componentName>
+func (options *WatchComponentConfigOpts) Execute(args []string) error {
+	configType, err := options.configType()
+	if err != nil {
+		return err
+	}
+	componentName, err := processComponentName(options.Args.Component, options.Instance)
+	if err != nil {
+		return err
+	}
+
+	cmOptions, err := options.configManagerOptions()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if options.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.Duration)
+		defer cancel()
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	outputFormat := CharReplacer.Replace(options.Format)
+	if outputFormat == "" {
+		outputFormat = GetCommandOptionWithDefault("config-watch", "format", DEFAULT_CONFIG_CHANGE_FORMAT)
+	}
+
+	componentConfig := cm.InitComponentConfig(componentName, configType).ForInstance(options.Instance)
+	subscription := componentConfig.Subscribe(ctx)
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-subscription.Events():
+			change := model.ConfigChange{
+				ComponentName: componentName,
+				InstanceId:    options.Instance,
+				ConfigType:    configType.String(),
+				ChangeType:    event.ChangeType.String(),
+				Key:           event.ConfigAttribute,
+			}
+			if event.ChangeType == config.Put {
+				value, _, err := componentConfig.Retrieve(ctx, event.ConfigAttribute)
+				if err != nil {
+					Warn.Printf("Unable to retrieve %s %s of component %s : %s", configType, event.ConfigAttribute, componentName, describeConfigError(err))
+				}
+				change.Value = value
+			}
+
+			result := CommandResult{
+				Format:    format.Format(outputFormat),
+				OutputAs:  toOutputType(options.OutputAs),
+				NameLimit: options.NameLimit,
+				Data:      []model.ConfigChange{change},
+			}
+			GenerateOutput(&result)
+		}
+	}
+}
+
+// This method lists the config types that the config commands accept, with the defaults they declare.
+// For example, using below command the config types can be list
+// voltctl config types
+func (options *ListConfigTypesOpts) Execute(args []string) error {
+	var data []ConfigTypeOutput
+	for _, configType := range config.ConfigTypes() {
+		var defaults []string
+		for key, value := range configType.Defaults() {
+			defaults = append(defaults, key+"="+value)
+		}
+		sort.Strings(defaults)
+		data = append(data, ConfigTypeOutput{Name: configType.String(), Defaults: strings.Join(defaults, ",")})
+	}
+
+	outputFormat := CharReplacer.Replace(options.Format)
+	if outputFormat == "" {
+		outputFormat = GetCommandOptionWithDefault("config-types", "format", DEFAULT_CONFIG_TYPES_FORMAT)
+	}
+	orderBy := options.OrderBy
+	if orderBy == "" {
+		orderBy = GetCommandOptionWithDefault("config-types", "order", "")
+	}
+
+	result := CommandResult{
+		Format:    format.Format(outputFormat),
+		Filter:    options.Filter,
+		OrderBy:   orderBy,
+		OutputAs:  toOutputType(options.OutputAs),
+		NameLimit: options.NameLimit,
+		Data:      data,
+	}
+	GenerateOutput(&result)
+	return nil
+}
+
+// This method writes the config of all components and config types to standard output as a versioned and
+// checksummed JSON archive, for use with config restore.
+// For example, using below command the config of a VOLTHA stack can be backed up
+// voltctl config backup > <file>
+func (options *BackupComponentConfigOpts) Execute(args []string) error {
+	cmOptions, err := options.configManagerOptions()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	archive, err := cm.Backup(ctx)
+	if err != nil {
+		return fmt.Errorf("Unable to back up the configuration of voltha components : %s", describeConfigError(err))
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// This method restores the config written by config backup, reading the archive from a file or, given -,
+// from standard input. The whole archive is validated before anything is written.
+// For example, using below command the config of a VOLTHA stack can be restored
+// voltctl config restore <file>
+// For example, using below command the config can be restored to another stack, removing entries that are not in the archive
+// voltctl config restore <file> --stack <stackName> --prune
+// Entries are restored as they were backed up; with --validate an archive holding values that are invalid
+// for their config type is refused instead
+// It exits with 3 if the archive is invalid, 2 if the kvstore is unavailable and 1 for other errors
+func (options *RestoreComponentConfigOpts) Execute(args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if options.Args.File == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(options.Args.File)
+	}
+	if err != nil {
+		return exitWithCode(ExitCodeFailure, fmt.Errorf("Unable to read archive : %s", err))
+	}
+
+	archive := &config.ConfigArchive{}
+	if err := json.Unmarshal(data, archive); err != nil {
+		return exitWithCode(ExitCodeValidationFailure, fmt.Errorf("Unable to decode archive %s : %s", options.Args.File, err))
+	}
+	var restoreOptions []config.RestoreOption
+	if options.Validate {
+		restoreOptions = append(restoreOptions, config.WithValidation())
+	}
+	if options.Prune {
+		restoreOptions = append(restoreOptions, config.WithPrune())
+	}
+	if err := config.ValidateArchive(archive, restoreOptions...); err != nil {
+		return exitWithCode(ExitCodeValidationFailure, fmt.Errorf("Invalid archive %s : %s", options.Args.File, err))
+	}
+	if options.DryRun {
+		fmt.Printf("Archive %s of %s is valid, %d config entries\n", options.Args.File, archive.Path, len(archive.Entries))
+		return nil
+	}
+
+	cmOptions, err := options.configManagerOptions()
+	if err != nil {
+		return exitWithCode(ExitCodeValidationFailure, err)
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return exitWithCode(ExitCodeConnectionFailure, err)
+	}
+	defer client.Close()
+
+	written, err := cm.Restore(ctx, archive, restoreOptions...)
+	if err != nil {
+		return exitWithCode(configErrorCode(err), fmt.Errorf("Restored %d of %d config entries, run restore again to complete it : %s",
+			written, len(archive.Entries), describeConfigError(err)))
+	}
+
+	fmt.Printf("Restored %d config entries\n", written)
+	return nil
+}
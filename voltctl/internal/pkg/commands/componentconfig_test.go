@@ -0,0 +1,62 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	"errors"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+	"reflect"
+	"testing"
+)
+
+func TestListedComponentNames(t *testing.T) {
+	if components, err := listedComponentNames(nil, ""); err != nil || components != nil {
+		t.Errorf("listedComponentNames returned %v, %v without arguments, expected all components", components, err)
+	}
+	if components, err := listedComponentNames([]string{"rw-core", "ofagent"}, "rw-core-0"); err != nil || !reflect.DeepEqual(components, []string{"rw-core", "ofagent"}) {
+		t.Errorf("listedComponentNames returned %v, %v", components, err)
+	}
+	for _, components := range [][]string{nil, {"global"}, {"rw-core#pkg/a"}} {
+		if _, err := listedComponentNames(components, "rw-core-0"); err == nil {
+			t.Errorf("listedComponentNames accepted %v for an instance", components)
+		}
+	}
+}
+
+// expectValidationFailure checks that err is the ExitError of an argument refused before connecting to the kvstore
+func expectValidationFailure(t *testing.T, name string, err error) {
+	t.Helper()
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeValidationFailure {
+		t.Errorf("%s returned %v, expected a validation failure", name, err)
+	}
+}
+
+func TestSaveComponentConfigsValidates(t *testing.T) {
+	err := saveComponentConfigs(OutputOptions{}, KvStoreOptions{}, "config-set", config.ConfigTypeTracing,
+		[]string{"rw-core"}, "", config.TracingKeySamplingRatio, "2")
+	expectValidationFailure(t, "saveComponentConfigs with an invalid value", err)
+
+	err = saveComponentConfigs(OutputOptions{}, KvStoreOptions{}, "config-set", config.ConfigTypeTracing,
+		[]string{"rw-core#pkg/a"}, "", config.TracingKeyEnabled, "true")
+	expectValidationFailure(t, "saveComponentConfigs for a package", err)
+}
+
+func TestDeleteComponentConfigsChecksKeys(t *testing.T) {
+	err := deleteComponentConfigs(OutputOptions{}, KvStoreOptions{}, "tracing-clear", config.ConfigTypeTracing,
+		config.TracingKeys(), []string{"rw-core"}, "", "exporter")
+	expectValidationFailure(t, "deleteComponentConfigs with an unknown key", err)
+}
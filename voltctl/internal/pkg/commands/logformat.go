@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	flags "github.com/jessevdk/go-flags"
+	"github.com/opencord/voltctl/pkg/model"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+)
+
+// SetLogFormatOpts represents the supported CLI arguments for the logformat set command
+type SetLogFormatOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Set the log format for a single instance (pod) of the component only"`
+	Args     struct {
+		Key       string
+		Value     string
+		Component []string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ListLogFormatOpts represents the supported CLI arguments for the logformat list command
+type ListLogFormatOpts struct {
+	ListOutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"List the log format set for a single instance (pod) of the component"`
+	Args     struct {
+		Component []string
+	} `positional-args:"yes"`
+}
+
+// ClearLogFormatOpts represents the supported CLI arguments for the logformat clear command
+type ClearLogFormatOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Clear the log format set for a single instance (pod) of the component"`
+	Args     struct {
+		Key       string
+		Component []string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// LogFormatOpts represents the logformat commands
+type LogFormatOpts struct {
+	SetLogFormat   SetLogFormatOpts   `command:"set"`
+	ListLogFormat  ListLogFormatOpts  `command:"list"`
+	ClearLogFormat ClearLogFormatOpts `command:"clear"`
+}
+
+var logFormatOpts = LogFormatOpts{}
+
+// RegisterLogFormatCommands is used to register set, list and clear log format of components
+func RegisterLogFormatCommands(parent *flags.Parser) {
+	_, err := parent.AddCommand("logformat", "logformat commands", "list, set and clear the log output format of components", &logFormatOpts)
+	if err != nil {
+		Error.Fatalf("Unable to register log format commands with voltctl command parser: %s", err.Error())
+	}
+}
+
+// This method sets a log format key for components.
+// The keys are format (json or console), caller and stacktrace (true or false).
+// For example, using below command all components can be switched to console output
+// voltctl logformat set format console
+// For example, using below command caller info can be enabled for specific components
+// voltctl logformat set caller true <componentName1> <componentName2>
+// For example, using below command stack traces can be enabled for a single instance of a component
+// voltctl logformat set stacktrace true <componentName> --instance <podName>
+func (options *SetLogFormatOpts) Execute(args []string) error {
+	return saveComponentConfigs(options.OutputOptions, options.KvStoreOptions, "logformat-set", config.ConfigTypeLogFormat,
+		options.Args.Component, options.Instance, options.Args.Key, options.Args.Value)
+}
+
+// This method clears a log format key for components, which then fall back to the global or built-in format.
+// Only the keys accepted by logformat set can be cleared.
+// For example, using below command the format set for a specific component can be cleared
+// voltctl logformat clear format <componentName>
+// For example, using below command the caller info set for a single instance of a component can be cleared
+// voltctl logformat clear caller <componentName> --instance <podName>
+func (options *ClearLogFormatOpts) Execute(args []string) error {
+	return deleteComponentConfigs(options.OutputOptions, options.KvStoreOptions, "logformat-clear", config.ConfigTypeLogFormat,
+		config.LogFormatKeys(), options.Args.Component, options.Instance, options.Args.Key)
+}
+
+// This method lists the log format keys set for components, without the built-in defaults.
+// For example, using below command the log format of all components can be list
+// voltctl logformat list
+// For example, using below command the log format set for a single instance can be list for the component
+// voltctl logformat list <componentName> --instance <podName>
+func (options *ListLogFormatOpts) Execute(args []string) error {
+	components, err := listedComponentNames(options.Args.Component, options.Instance)
+	if err != nil {
+		return err
+	}
+
+	return listComponentConfigs(options.ListOutputOptions, options.KvStoreOptions, configListing{
+		commandName:    "logformat-list",
+		configType:     config.ConfigTypeLogFormat,
+		format:         DEFAULT_COMPONENT_CONFIG_FORMAT,
+		instanceFormat: DEFAULT_INSTANCE_COMPONENT_CONFIG_FORMAT,
+		model:          model.LogFormat{},
+		row: func(componentName string, instanceId string, key string, value string) (interface{}, bool) {
+			logFormat := model.LogFormat{InstanceId: instanceId}
+			logFormat.PopulateFrom(componentName, key, value)
+			return logFormat, true
+		},
+	}, components, options.Instance)
+}
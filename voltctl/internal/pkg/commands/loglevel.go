@@ -25,8 +25,13 @@ import (
 	"github.com/opencord/voltha-lib-go/v3/pkg/config"
 	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
 	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+	"math"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -35,21 +40,84 @@ const (
 	defaultKVStoreTimeout = 1 //in seconds
 	defaultKVStoreHost    = "127.0.0.1"
 	defaultKVStorePort    = 2379 // Consul = 8500; Etcd = 2379
+	defaultKVStorePrefix  = "/service/voltha"
+	kvStoreStackPrefix    = "/service/"
 	defaultComponentName  = "global"
 	defaultPackageName    = "default"
+
+	// defaultConfigWorkers bounds the number of components that are read or updated at the same time
+	defaultConfigWorkers = 16
+
+	// acknowledgementPollInterval is how often set --wait checks the acknowledgements of the components
+	acknowledgementPollInterval = 500 * time.Millisecond
+)
+
+// Exit codes of the commands updating the config of components, such as loglevel set and clear, also
+// reported per component as LogLevelOutput.ErrorCode
+const (
+	ExitCodeSuccess           = 0
+	ExitCodeFailure           = 1
+	ExitCodeConnectionFailure = 2
+	ExitCodeValidationFailure = 3
+	ExitCodePartialFailure    = 4
+	ExitCodeNotAcknowledged   = 5
 )
 
-// LogLevelOutput represents the  output structure for the loglevel
+// LogLevelOutput represents the output structure for the loglevel, also used by the other commands
+// updating the config of components. ErrorCode is one of the exit codes, ExitCodeSuccess for a component
+// that was updated
 type LogLevelOutput struct {
 	ComponentName string
 	Status        string
 	Error         string
+	ErrorCode     int
+}
+
+// KvStoreOptions selects the VOLTHA stack whose configuration a command acts on,
+// when several stacks share one kvstore, and how kvstore operations are retried
+type KvStoreOptions struct {
+	KvPrefix     string        `long:"kv-prefix" value-name:"PREFIX" description:"kvstore path prefix of the VOLTHA stack (default: /service/voltha)"`
+	Stack        string        `long:"stack" value-name:"STACK" description:"Name of the VOLTHA stack, short for --kv-prefix /service/<STACK>"`
+	Retries      int           `long:"kv-retries" default:"4" value-name:"COUNT" description:"Number of retries of a kvstore operation failing with a transient error"`
+	RetryBackoff time.Duration `long:"kv-retry-backoff" default:"100ms" value-name:"DURATION" description:"Wait before the first retry, doubled for every further retry"`
+}
+
+// configManagerOptions validates the options and returns the matching ConfigManager options
+func (options *KvStoreOptions) configManagerOptions() ([]config.ConfigManagerOption, error) {
+	var pathPrefix string
+	switch {
+	case options.KvPrefix != "" && options.Stack != "":
+		return nil, errors.New("Only one of --kv-prefix and --stack can be given")
+	case options.Stack != "":
+		if strings.Contains(options.Stack, "/") {
+			return nil, fmt.Errorf("Invalid stack name %q", options.Stack)
+		}
+		pathPrefix = kvStoreStackPrefix + options.Stack
+	case options.KvPrefix != "":
+		pathPrefix = options.KvPrefix
+	default:
+		pathPrefix = defaultKVStorePrefix
+	}
+
+	if options.Retries < 0 || options.RetryBackoff < 0 {
+		return nil, errors.New("--kv-retries and --kv-retry-backoff can't be negative")
+	}
+	retryPolicy := config.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = options.Retries + 1
+	retryPolicy.InitialBackoff = options.RetryBackoff
+
+	return []config.ConfigManagerOption{config.WithPathPrefix(pathPrefix), config.WithRetryPolicy(retryPolicy)}, nil
 }
 
 // SetLogLevelOpts represents the supported CLI arguments for the loglevel set command
 type SetLogLevelOpts struct {
 	OutputOptions
-	Args struct {
+	KvStoreOptions
+	Device      string        `long:"device" value-name:"DEVICE_ID" description:"Set the log level for a single device only"`
+	Instance    string        `long:"instance" value-name:"INSTANCE_ID" description:"Set the log level for a single instance (pod) of the component only"`
+	Wait        bool          `long:"wait" description:"Wait until every running instance of the components has applied the level"`
+	WaitTimeout time.Duration `long:"wait-timeout" default:"30s" value-name:"DURATION" description:"How long --wait waits for the components"`
+	Args        struct {
 		Level     string
 		Component []string
 	} `positional-args:"yes" required:"yes"`
@@ -58,7 +126,11 @@ type SetLogLevelOpts struct {
 // ListLogLevelOpts represents the supported CLI arguments for the loglevel list command
 type ListLogLevelsOpts struct {
 	ListOutputOptions
-	Args struct {
+	KvStoreOptions
+	Device    string `long:"device" value-name:"DEVICE_ID" description:"List the log levels set for a single device"`
+	Instance  string `long:"instance" value-name:"INSTANCE_ID" description:"List the log levels set for a single instance (pod) of the component"`
+	Effective bool   `long:"effective" description:"List the log levels in effect, including those inherited from the global config and the built-in default, with their source"`
+	Args      struct {
 		Component []string
 	} `positional-args:"yes" required:"yes"`
 }
@@ -66,180 +138,742 @@ type ListLogLevelsOpts struct {
 // ClearLogLevelOpts represents the supported CLI arguments for the loglevel clear command
 type ClearLogLevelsOpts struct {
 	OutputOptions
-	Args struct {
+	KvStoreOptions
+	Device   string `long:"device" value-name:"DEVICE_ID" description:"Clear the log level set for a single device"`
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Clear the log level set for a single instance (pod) of the component"`
+	Args     struct {
 		Component []string
 	} `positional-args:"yes" required:"yes"`
 }
 
+// GetLogLevelOpts represents the supported CLI arguments for the loglevel get command
+type GetLogLevelOpts struct {
+	KvStoreOptions
+	Device   string `long:"device" value-name:"DEVICE_ID" description:"Get the log level set for a single device"`
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Get the log level set for a single instance (pod) of the component"`
+	Args     struct {
+		Component string
+	} `positional-args:"yes"`
+}
+
 // LogLevelOpts represents the loglevel commands
 type LogLevelOpts struct {
 	SetLogLevel    SetLogLevelOpts    `command:"set"`
+	GetLogLevel    GetLogLevelOpts    `command:"get"`
 	ListLogLevels  ListLogLevelsOpts  `command:"list"`
 	ClearLogLevels ClearLogLevelsOpts `command:"clear"`
+	Sampling       LogSamplingOpts    `command:"sampling" description:"list, set and clear the log sampling of components"`
 }
 
 var logLevelOpts = LogLevelOpts{}
 
 const (
-	DEFAULT_LOGLEVELS_FORMAT       = "table{{ .ComponentName }}\t{{.PackageName}}\t{{.Level}}"
-	DEFAULT_LOGLEVEL_RESULT_FORMAT = "table{{ .ComponentName }}\t{{.Status}}\t{{.Error}}"
+	DEFAULT_LOGLEVELS_FORMAT          = "table{{ .ComponentName }}\t{{.PackageName}}\t{{.Level}}"
+	DEFAULT_DEVICE_LOGLEVELS_FORMAT   = "table{{ .ComponentName }}\t{{.DeviceId}}\t{{.PackageName}}\t{{.Level}}"
+	DEFAULT_INSTANCE_LOGLEVELS_FORMAT = "table{{ .ComponentName }}\t{{.InstanceId}}\t{{.PackageName}}\t{{.Level}}"
+	DEFAULT_SCOPED_LOGLEVELS_FORMAT   = "table{{ .ComponentName }}\t{{.DeviceId}}\t{{.InstanceId}}\t{{.PackageName}}\t{{.Level}}"
+	DEFAULT_LOGLEVEL_RESULT_FORMAT    = "table{{ .ComponentName }}\t{{.Status}}\t{{.Error}}"
+	EFFECTIVE_LOGLEVELS_FORMAT_SUFFIX = "\t{{.Source}}"
 )
 
-// RegisterLogLevelCommands is used to  register set,list and clear loglevel of components
+// RegisterLogLevelCommands is used to  register set,list and clear loglevel of components.
+// It also registers the logformat, tracing and config commands, which share the kvstore options and output
+// of the loglevel commands, so that voltctl gets them by registering this one
 func RegisterLogLevelCommands(parent *flags.Parser) {
-	_, err := parent.AddCommand("loglevel", "loglevel commands", "list,set and clear log levels of components", &logLevelOpts)
+	_, err := parent.AddCommand("loglevel", "loglevel commands", "get,list,set and clear log levels of components", &logLevelOpts)
 	if err != nil {
 		Error.Fatalf("Unable to register log level commands with voltctl command parser: %s", err.Error())
 	}
+	RegisterLogFormatCommands(parent)
+	RegisterTracingCommands(parent)
+	RegisterComponentConfigCommands(parent)
+}
+
+// splitComponentArg splits a <componentName>[#<packageName>] argument on its first unescaped '#'.
+// A '#' or '\' that is part of a name has to be escaped with '\', for example rw-core#pkg\#1
+// refers to package pkg#1 of component rw-core. It returns an error if the argument
+// contains more than one unescaped '#'
+func splitComponentArg(arg string) (string, string, bool, error) {
+	var (
+		parts   []string
+		current strings.Builder
+		escaped bool
+	)
+	for _, r := range arg {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '#':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if escaped {
+		return "", "", false, fmt.Errorf("Trailing escape character in %q", arg)
+	}
+	parts = append(parts, current.String())
+
+	switch len(parts) {
+	case 1:
+		return parts[0], "", false, nil
+	case 2:
+		return parts[0], parts[1], true, nil
+	default:
+		return "", "", false, fmt.Errorf("Too many '#' separators in %q, use '\\#' for a '#' within a name", arg)
+	}
 }
 
 // processCommandArgs stores  the component name and package names given in command arguments to LogLevel
-// It checks the given argument has # key or not, if # is present then split the argument for # then stores first part as component name
-// and second part as package name
-func processCommandArgs(Components []string) ([]model.LogLevel, error) {
+// It splits each argument on its first unescaped '#' and stores the first part as component name
+// and the second part as package name. Arguments without a '#' refer to the default package.
+// Names are kept as given; the config package takes care of encoding them into kvstore keys.
+// A non empty deviceId scopes every entry to that device and a non empty instanceId to that instance of
+// the component, neither is supported for the global level
+func processCommandArgs(Components []string, deviceId string, instanceId string) ([]model.LogLevel, error) {
+	if err := validateScope(deviceId, instanceId); err != nil {
+		return nil, err
+	}
 
 	var logLevelConfig []model.LogLevel
 	for _, component := range Components {
-		logConfig := model.LogLevel{}
-		if strings.Contains(component, "#") {
-			val := strings.Split(component, "#")
-			if val[0] == defaultComponentName {
+		componentName, packageName, hasPackage, err := splitComponentArg(component)
+		if err != nil {
+			return nil, err
+		}
+		if componentName == "" {
+			return nil, fmt.Errorf("Component name is missing in %q", component)
+		}
+
+		if componentName == defaultComponentName && deviceId != "" {
+			return nil, errors.New("global level doesn't support device scope, specify the component")
+		}
+		if componentName == defaultComponentName && instanceId != "" {
+			return nil, errors.New("global level doesn't support instance scope, specify the component")
+		}
+
+		logConfig := model.LogLevel{ComponentName: componentName, PackageName: defaultPackageName, DeviceId: deviceId, InstanceId: instanceId}
+		if hasPackage {
+			if componentName == defaultComponentName {
 				return nil, errors.New("global level doesn't support packageName")
 			}
-			logConfig.ComponentName = val[0]
-			logConfig.PackageName = strings.ReplaceAll(val[1], "/", "#")
-		} else {
-			logConfig.ComponentName = component
-			logConfig.PackageName = defaultPackageName
+			if packageName == "" {
+				return nil, fmt.Errorf("Package name is missing in %q", component)
+			}
+			logConfig.PackageName = packageName
 		}
 		logLevelConfig = append(logLevelConfig, logConfig)
 	}
 	return logLevelConfig, nil
 }
 
+// validateScope checks that at most one of the device and instance scopes is given
+func validateScope(deviceId string, instanceId string) error {
+	if deviceId != "" && instanceId != "" {
+		return errors.New("Only one of --device and --instance can be given")
+	}
+	return nil
+}
+
+// logLevelComponentConfig returns the loglevel ComponentConfig of the component, device or instance lConfig refers to
+func logLevelComponentConfig(cm *config.ConfigManager, lConfig model.LogLevel) *config.ComponentConfig {
+	return cm.InitComponentConfig(lConfig.ComponentName, config.ConfigTypeLogLevel).ForDevice(lConfig.DeviceId).ForInstance(lConfig.InstanceId)
+}
+
+// supportedLogLevels returns the levels known to the log library in order of severity. The levels are
+// probed upwards from DebugLevel until the library stops recognising them, so that levels added to the
+// library, for example NONE, are accepted and listed without changes to voltctl
+func supportedLogLevels() []log.LogLevel {
+	var levels []log.LogLevel
+	for l := log.DebugLevel; l < math.MaxInt8; l++ {
+		if _, err := log.LogLevelToString(l); err != nil {
+			break
+		}
+		levels = append(levels, l)
+	}
+	return levels
+}
+
+// normalizeLogLevel validates a log level given in any case, or as its numeric value in the log library,
+// and returns the level name as it is stored in the kvstore. For example debug, Debug and 0 all return
+// DEBUG. Names are checked by the schema of the loglevel config type, as Save checks them
+func normalizeLogLevel(level string) (string, error) {
+	name := strings.TrimSpace(level)
+	if n, err := strconv.Atoi(name); err == nil && n >= math.MinInt8 && n <= math.MaxInt8 {
+		if levelName, err := log.LogLevelToString(log.LogLevel(n)); err == nil {
+			name = levelName
+		}
+	}
+	if normalized, err := config.ConfigTypeLogLevel.Validate(defaultPackageName, name); err == nil {
+		return normalized, nil
+	}
+
+	var allowed []string
+	for _, l := range supportedLogLevels() {
+		levelName, _ := log.LogLevelToString(l)
+		allowed = append(allowed, fmt.Sprintf("%s(%d)", levelName, l))
+	}
+	return "", fmt.Errorf("Unknown log level %s. Allowed values are %s, given by name or number",
+		strings.TrimSpace(level), strings.Join(allowed, ","))
+}
+
+// isValidLogLevel checks whether a stored log level is still accepted by the loglevel config type
+func isValidLogLevel(level string) bool {
+	_, err := config.ConfigTypeLogLevel.Validate(defaultPackageName, level)
+	return err == nil
+}
+
+// connectConfigManager creates a kvstore client, checks that the kvstore is reachable and returns a
+// ConfigManager using the client, configured with opts. The client has to be closed by the caller
+func connectConfigManager(ctx context.Context, opts ...config.ConfigManagerOption) (*config.ConfigManager, kvstore.Client, error) {
+	client, err := kvstore.NewEtcdClient(defaultKVStoreHost+":"+strconv.Itoa(defaultKVStorePort), defaultKVStoreTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to create client %s", err)
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, defaultKVStoreTimeout*time.Second)
+	defer cancel()
+	if !client.IsConnectionUp(probeCtx) {
+		client.Close()
+		return nil, nil, fmt.Errorf("Unable to connect to kvstore at %s:%d", defaultKVStoreHost, defaultKVStorePort)
+	}
+
+	cm := config.NewConfigManagerWithOptions(client, append([]config.ConfigManagerOption{
+		config.WithKVStoreAddress(defaultKVStoreType, defaultKVStoreHost, defaultKVStorePort),
+		config.WithTimeout(defaultKVStoreTimeout * time.Second),
+	}, opts...)...)
+	return cm, client, nil
+}
+
+// configErrorCode classifies the error returned for a single component. Errors of the kvstore client
+// that were not wrapped by the config package, such as an etcd Unavailable error, are classified alike
+func configErrorCode(err error) int {
+	switch config.ErrorKind(err) {
+	case config.ErrUnavailable, config.ErrTimeout:
+		return ExitCodeConnectionFailure
+	case config.ErrInvalidValue:
+		return ExitCodeValidationFailure
+	default:
+		return ExitCodeFailure
+	}
+}
+
+// describeConfigError turns an error of the config package into a message that tells the user what to do about it
+func describeConfigError(err error) string {
+	switch {
+	case errors.Is(err, config.ErrUnavailable):
+		return fmt.Sprintf("kvstore at %s:%d is unavailable, check that it is running and reachable (%s)", defaultKVStoreHost, defaultKVStorePort, err)
+	case errors.Is(err, config.ErrTimeout):
+		return fmt.Sprintf("kvstore at %s:%d did not respond in time, retry or raise --kv-retries (%s)", defaultKVStoreHost, defaultKVStorePort, err)
+	case errors.Is(err, config.ErrInvalidValue):
+		return fmt.Sprintf("the value was rejected, check the arguments (%s)", err)
+	case errors.Is(err, config.ErrConflict):
+		return fmt.Sprintf("the configuration was changed concurrently, retry the command (%s)", err)
+	case errors.Is(err, config.ErrNotFound):
+		return fmt.Sprintf("no such configuration entry (%s)", err)
+	default:
+		return err.Error()
+	}
+}
+
+// configExitCode derives the exit code of a command updating components from the results of all of them.
+// If only some of the components failed it is ExitCodePartialFailure. If all of them failed it is
+// their common error code, or ExitCodeFailure if they failed for different reasons
+func configExitCode(output []LogLevelOutput) int {
+	failed, code := 0, ExitCodeSuccess
+	for _, o := range output {
+		switch {
+		case o.ErrorCode == ExitCodeSuccess:
+			continue
+		case failed == 0:
+			code = o.ErrorCode
+		case o.ErrorCode != code:
+			code = ExitCodeFailure
+		}
+		failed++
+	}
+	if failed > 0 && failed < len(output) {
+		return ExitCodePartialFailure
+	}
+	return code
+}
+
+// ExitError is returned by the commands that exit with a specific exit code, such as loglevel set and clear.
+// Commands return it rather than exiting themselves, so that their deferred cleanup, like closing the
+// kvstore client, still runs. The command parser doesn't know about it and exits with 1 for every error,
+// so main has to pass the error of the parser to ExitOnError for the code to reach the shell
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitOnError reports err and exits with its code if err is, or wraps, an ExitError, and returns otherwise.
+// main calls it with the error of the command parser before handling the error as for any other command
+//
+//	if _, err := parser.Parse(); err != nil {
+//		commands.ExitOnError(err)
+//		...
+//	}
+func ExitOnError(err error) {
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		return
+	}
+	if exitErr.Err != nil {
+		Error.Println(exitErr.Err)
+	}
+	os.Exit(exitErr.Code)
+}
+
+// exitWithCode returns the ExitError that makes voltctl report err, if any, and exit with the given code
+func exitWithCode(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// generateResultOutput prints the result of every component of a command like loglevel set, in the
+// format given by options or configured for commandName. It returns the ExitError for the exit code
+// the results call for, or nil if all components succeeded
+func generateResultOutput(options OutputOptions, commandName string, output []LogLevelOutput) error {
+	outputFormat := CharReplacer.Replace(options.Format)
+	if outputFormat == "" {
+		outputFormat = GetCommandOptionWithDefault(commandName, "format", DEFAULT_LOGLEVEL_RESULT_FORMAT)
+	}
+	result := CommandResult{
+		Format:    format.Format(outputFormat),
+		OutputAs:  toOutputType(options.OutputAs),
+		NameLimit: options.NameLimit,
+		Data:      output,
+	}
+
+	GenerateOutput(&result)
+	if code := configExitCode(output); code != ExitCodeSuccess {
+		return exitWithCode(code, nil)
+	}
+	return nil
+}
+
+// generateFailedOutput reports a failure that occurred before any component was updated, such as an
+// invalid argument or an unreachable kvstore, as the result of every component named in componentArgs,
+// or of the global component if none is named. The result is printed like generateResultOutput does, so
+// that scripts reading the JSON or YAML output see the failure too, and the ExitError for code is returned
+func generateFailedOutput(options OutputOptions, commandName string, componentArgs []string, code int, err error) error {
+	if len(componentArgs) == 0 {
+		componentArgs = []string{defaultComponentName}
+	}
+	output := make([]LogLevelOutput, len(componentArgs))
+	for i, componentArg := range componentArgs {
+		output[i] = LogLevelOutput{ComponentName: componentArg, Status: "Failure", Error: err.Error(), ErrorCode: code}
+	}
+	generateResultOutput(options, commandName, output)
+	return exitWithCode(code, err)
+}
+
+// forEachConcurrently calls fn for every index in [0, n), running at most workers calls at the same time.
+// It returns once all calls have completed
+func forEachConcurrently(n int, workers int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
 // This method set loglevel for components.
+// It exits with ExitCodeValidationFailure for invalid arguments, ExitCodeConnectionFailure if the kvstore
+// can't be reached and ExitCodePartialFailure if only some of the components could be updated
+// The level is case-insensitive and may also be given as its numeric value, for example 0 for DEBUG
 // For example, using below command loglevel can be set for specific component with default packageName
 // voltctl loglevel set level  <componentName>
 // For example, using below command loglevel can be set for specific component with specific packageName
 // voltctl loglevel set level <componentName#packageName>
 // For example, using below command loglevel can be set for more than one component for default package and other component for specific packageName
 // voltctl loglevel set level <componentName1#packageName> <componentName2>
+// For example, using below command loglevel can be set for a single device handled by the component
+// voltctl loglevel set level <componentName#packageName> --device <deviceId>
+// For example, using below command loglevel can be set for a single instance of a replicated component,
+// overriding the level of the component for that instance only
+// voltctl loglevel set level <componentName#packageName> --instance <podName>
+// For example, using below command loglevel can be set for a component of one of the VOLTHA stacks sharing a kvstore
+// voltctl loglevel set level <componentName> --stack <stackName>
+// For example, using below command set waits until every running instance of the component has applied the level
+// and exits with ExitCodeNotAcknowledged, listing the lagging instances, if that takes longer than a minute
+// voltctl loglevel set level <componentName> --wait --wait-timeout 1m
 func (options *SetLogLevelOpts) Execute(args []string) error {
 	var (
 		logLevelConfig []model.LogLevel
 		err            error
 	)
 
-	if options.Args.Level != "" {
-		if _, err := log.StringToLogLevel(options.Args.Level); err != nil {
-			return fmt.Errorf("Unknown log level %s. Allowed values are <INFO>,<DEBUG>,<ERROR>,<WARN>,<FATAL>", options.Args.Level)
-		}
+	level, err := normalizeLogLevel(options.Args.Level)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-set", options.Args.Component, ExitCodeValidationFailure, err)
 	}
 
 	if len(options.Args.Component) == 0 {
 		var component []string
 		component = append(component, defaultComponentName)
-		logLevelConfig, err = processCommandArgs(component)
+		logLevelConfig, err = processCommandArgs(component, options.Device, options.Instance)
 	} else {
-		logLevelConfig, err = processCommandArgs(options.Args.Component)
+		logLevelConfig, err = processCommandArgs(options.Args.Component, options.Device, options.Instance)
+	}
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-set", options.Args.Component, ExitCodeValidationFailure, err)
+	}
+	if options.Wait && options.Device != "" {
+		return generateFailedOutput(options.OutputOptions, "loglevel-set", options.Args.Component, ExitCodeValidationFailure, errors.New("--wait can't be combined with --device, components don't acknowledge device log levels"))
 	}
+
+	cmOptions, err := options.configManagerOptions()
 	if err != nil {
-		return fmt.Errorf("%s", err)
+		return generateFailedOutput(options.OutputOptions, "loglevel-set", options.Args.Component, ExitCodeValidationFailure, err)
 	}
 
-	client, err := kvstore.NewEtcdClient(defaultKVStoreHost+":"+strconv.Itoa(defaultKVStorePort), defaultKVStoreTimeout)
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-set", options.Args.Component, ExitCodeConnectionFailure, err)
+	}
 	defer client.Close()
+
+	output := make([]LogLevelOutput, len(logLevelConfig))
+	forEachConcurrently(len(logLevelConfig), defaultConfigWorkers, func(i int) {
+		lConfig := logLevelConfig[i]
+		logConfig := logLevelComponentConfig(cm, lConfig)
+
+		if err := logConfig.Save(ctx, lConfig.PackageName, level); err != nil {
+			output[i] = LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Failure", Error: describeConfigError(err), ErrorCode: configErrorCode(err)}
+		} else {
+			output[i] = LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Success"}
+		}
+	})
+	if options.Wait {
+		waitForAcknowledgements(ctx, cm, logLevelConfig, level, options.WaitTimeout, output)
+	}
+
+	return generateResultOutput(options.OutputOptions, "loglevel-set", output)
+}
+
+// acknowledgementTarget is the stored entry the running instances have to resolve a level set for one
+// component from, with at least the version the set wrote, see config.ConfigOrigin
+type acknowledgementTarget struct {
+	source  config.ConfigSource
+	version int64
+}
+
+// newAcknowledgementTarget reads the version of the level set for lConfig
+func newAcknowledgementTarget(ctx context.Context, cm *config.ConfigManager, lConfig model.LogLevel) (acknowledgementTarget, error) {
+	target := acknowledgementTarget{source: config.ConfigSourceComponent}
+	switch {
+	case lConfig.InstanceId != "":
+		target.source = config.ConfigSourceInstance
+	case lConfig.ComponentName == defaultComponentName:
+		target.source = config.ConfigSourceGlobal
+	}
+	var err error
+	target.version, _, err = logLevelComponentConfig(cm, lConfig).RetrieveVersion(ctx, lConfig.PackageName)
+	return target, err
+}
+
+// componentsWithDefaultLevel returns the components that set a default level of their own
+func componentsWithDefaultLevel(ctx context.Context, cm *config.ConfigManager) (map[string]bool, error) {
+	componentConfigs, err := cm.RetrieveAllComponents(ctx, config.ConfigTypeLogLevel)
 	if err != nil {
-		return fmt.Errorf("Unable to create client %s", err)
+		return nil, err
 	}
-	cm := config.NewConfigManager(client, defaultKVStoreType, defaultKVStoreHost, defaultKVStorePort, defaultKVStoreTimeout)
+	res := make(map[string]bool)
+	for componentName, levels := range componentConfigs {
+		if _, ok := levels[defaultPackageName]; ok {
+			res[componentName] = true
+		}
+	}
+	return res, nil
+}
 
-	var output []LogLevelOutput
+// expectedAcknowledgements returns the components that have to acknowledge a level set for lConfig,
+// given the acknowledgements of all components. A level set for a component has to be acknowledged by that
+// component, the global default level by every component that doesn't set a default level of its own
+func expectedAcknowledgements(lConfig model.LogLevel, ownDefaults map[string]bool, acks map[string]map[string]config.Acknowledgement) []string {
+	if lConfig.ComponentName != defaultComponentName {
+		return []string{lConfig.ComponentName}
+	}
 
-	for _, lConfig := range logLevelConfig {
+	var components []string
+	for componentName := range acks {
+		if componentName != defaultComponentName && !ownDefaults[componentName] {
+			components = append(components, componentName)
+		}
+	}
+	sort.Strings(components)
+	return components
+}
 
-		logConfig := cm.InitComponentConfig(lConfig.ComponentName, config.ConfigTypeLogLevel)
+// laggingInstances returns the running instances of the components that have not applied level to the package
+// from the target entry, as <componentName>/<instance>. A non empty instanceId restricts the check to that
+// instance. The bool result is false if no instance of the components is running
+func laggingInstances(components []string, instanceId string, packageName string, level string, target acknowledgementTarget,
+	acks map[string]map[string]config.Acknowledgement, liveness *config.LivenessTracker, now time.Time) ([]string, bool) {
+	var lagging []string
+	alive := false
+	for _, componentName := range components {
+		for instance, ack := range acks[componentName] {
+			if !liveness.Alive(now, componentName, ack) || (instanceId != "" && instance != instanceId) {
+				continue
+			}
+			alive = true
+			if ack.Applied[packageName] != level || !ack.Acknowledges(packageName, target.source, target.version) {
+				lagging = append(lagging, componentName+"/"+instance)
+			}
+		}
+	}
+	sort.Strings(lagging)
+	return lagging, alive
+}
 
-		err := logConfig.Save(context.Background(), lConfig.PackageName, strings.ToUpper(options.Args.Level))
-		if err != nil {
-			output = append(output, LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Failure", Error: err.Error()})
-		} else {
-			output = append(output, LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Success"})
+// waitForAcknowledgements waits until the running instances of the components have applied the level set for
+// every successful entry of output, or the timeout passes. Entries that were not acknowledged in time are
+// updated with the lagging instances. The versions of the levels set and the components with a default
+// level of their own are read once, only the acknowledgements are polled
+func waitForAcknowledgements(ctx context.Context, cm *config.ConfigManager, logLevelConfig []model.LogLevel, level string, timeout time.Duration, output []LogLevelOutput) {
+	pending := make(map[int]string)
+	targets := make(map[int]acknowledgementTarget)
+	var (
+		ownDefaults map[string]bool
+		err         error
+	)
+	for i := range output {
+		if output[i].ErrorCode != ExitCodeSuccess {
+			continue
+		}
+		if targets[i], err = newAcknowledgementTarget(ctx, cm, logLevelConfig[i]); err != nil {
+			output[i] = LogLevelOutput{ComponentName: output[i].ComponentName, Status: "Lagging", Error: "unable to retrieve the level set: " + describeConfigError(err), ErrorCode: ExitCodeNotAcknowledged}
+			continue
 		}
+		if logLevelConfig[i].ComponentName == defaultComponentName && ownDefaults == nil {
+			if ownDefaults, err = componentsWithDefaultLevel(ctx, cm); err != nil {
+				output[i] = LogLevelOutput{ComponentName: output[i].ComponentName, Status: "Lagging", Error: "unable to retrieve the component levels: " + describeConfigError(err), ErrorCode: ExitCodeNotAcknowledged}
+				continue
+			}
+		}
+		pending[i] = "no running instance has acknowledged the level"
+	}
 
+	liveness := config.NewLivenessTracker()
+	deadline := time.Now().Add(timeout)
+	for len(pending) > 0 {
+		acks, ackErr := cm.RetrieveAllAcknowledgements(ctx, config.ConfigTypeLogLevel)
+		now := time.Now()
+		liveness.Observe(now, acks)
+		for i := range pending {
+			if ackErr != nil {
+				pending[i] = "unable to retrieve acknowledgements: " + describeConfigError(ackErr)
+				continue
+			}
+			components := expectedAcknowledgements(logLevelConfig[i], ownDefaults, acks)
+			lagging, alive := laggingInstances(components, logLevelConfig[i].InstanceId, logLevelConfig[i].PackageName, level, targets[i], acks, liveness, now)
+			switch {
+			case !alive:
+				pending[i] = "no running instance has acknowledged the level"
+			case len(lagging) > 0:
+				pending[i] = "not applied by " + strings.Join(lagging, ", ")
+			default:
+				output[i].Status = "Applied"
+				delete(pending, i)
+			}
+		}
+
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(acknowledgementPollInterval)
 	}
 
-	outputFormat := CharReplacer.Replace(options.Format)
-	if outputFormat == "" {
-		outputFormat = GetCommandOptionWithDefault("loglevel-set", "format", DEFAULT_LOGLEVEL_RESULT_FORMAT)
+	for i, reason := range pending {
+		output[i] = LogLevelOutput{ComponentName: output[i].ComponentName, Status: "Lagging", Error: reason, ErrorCode: ExitCodeNotAcknowledged}
 	}
-	result := CommandResult{
-		Format:    format.Format(outputFormat),
-		OutputAs:  toOutputType(options.OutputAs),
-		NameLimit: options.NameLimit,
-		Data:      output,
+}
+
+// This method get loglevel of a single package of a component and prints only the level, for use in scripts.
+// It fails if no level is stored for the package.
+// For example, using below command loglevel of the default package of a component can be get
+// voltctl loglevel get <componentName>
+// For example, using below command loglevel of a specific package of a component can be get
+// voltctl loglevel get <componentName#packageName>
+func (options *GetLogLevelOpts) Execute(args []string) error {
+	component := options.Args.Component
+	if component == "" {
+		component = defaultComponentName
 	}
+	logLevelConfig, err := processCommandArgs([]string{component}, options.Device, options.Instance)
+	if err != nil {
+		return err
+	}
+	lConfig := logLevelConfig[0]
 
-	GenerateOutput(&result)
+	cmOptions, err := options.configManagerOptions()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	logConfig := logLevelComponentConfig(cm, lConfig)
+	level, found, err := logConfig.Retrieve(ctx, lConfig.PackageName)
+	if err != nil {
+		return fmt.Errorf("Unable to retrieve loglevel of component %s package %s : %s", lConfig.ComponentName, lConfig.PackageName, describeConfigError(err))
+	}
+	if !found {
+		return fmt.Errorf("No loglevel is set for component %s package %s", lConfig.ComponentName, lConfig.PackageName)
+	}
+
+	fmt.Println(level)
 	return nil
 }
 
 // This method list loglevel for components.
 // For example, using below command loglevel can be list for specific component
 // voltctl loglevel list  <componentName>
-// For example, using below command loglevel can be list for all the components with all the packageName
+// For example, using below command loglevel can be list for all the components with all the packageName,
+// along with the loglevels set for single devices or instances, if any
 // voltctl loglevel list
+// For example, using below command loglevel set for a single device can be list for the component
+// voltctl loglevel list <componentName> --device <deviceId>
+// For example, using below command loglevel set for a single instance can be list for the component
+// voltctl loglevel list <componentName> --instance <podName>
+// For example, using below command the loglevels in effect for a component can be list, with the global
+// config or built-in default they are inherited from
+// voltctl loglevel list <componentName> --effective
 func (options *ListLogLevelsOpts) Execute(args []string) error {
 
 	var (
-		data           []model.LogLevel
-		componentList  []string
-		logLevelConfig map[string]string
-		err            error
+		data          []model.LogLevel
+		componentList []string
+		scopedConfigs []config.ScopedConfig
+		scoped        bool
+		err           error
 	)
 
-	client, err := kvstore.NewEtcdClient(defaultKVStoreHost+":"+strconv.Itoa(defaultKVStorePort), defaultKVStoreTimeout)
-	defer client.Close()
+	if err = validateScope(options.Device, options.Instance); err != nil {
+		return err
+	}
+
+	cmOptions, err := options.configManagerOptions()
 	if err != nil {
-		return fmt.Errorf("Unable to create client %s", err)
+		return err
 	}
-	cm := config.NewConfigManager(client, defaultKVStoreType, defaultKVStoreHost, defaultKVStorePort, defaultKVStoreTimeout)
 
-	if len(options.Args.Component) == 0 {
-		componentList, err = cm.RetrieveComponentList(context.Background(), config.ConfigTypeLogLevel)
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if options.Effective {
+		data, err = effectiveLogLevels(ctx, cm, options.Args.Component, options.Device, options.Instance)
 		if err != nil {
-			return fmt.Errorf("Unable to retrieve list of voltha components : %s ", err)
+			return err
+		}
+	} else if len(options.Args.Component) == 0 && options.Device == "" && options.Instance == "" {
+		// All levels can be read at once, including the device and instance scoped ones, so that
+		// the levels of components that are only set for a device or instance are listed as well
+		scopedConfigs, err = cm.RetrieveAllScopes(ctx, config.ConfigTypeLogLevel)
+		if err != nil {
+			return fmt.Errorf("Unable to retrieve loglevel configuration of voltha components : %s ", describeConfigError(err))
 		}
 	} else {
-		for _, component := range options.Args.Component {
-			componentList = append(componentList, component)
+		if len(options.Args.Component) == 0 {
+			componentList, err = cm.RetrieveComponentList(ctx, config.ConfigTypeLogLevel)
+			if err != nil {
+				return fmt.Errorf("Unable to retrieve list of voltha components : %s ", describeConfigError(err))
+			}
+		} else {
+			componentList = options.Args.Component
 		}
-	}
 
-	for _, componentName := range componentList {
-		logConfig := cm.InitComponentConfig(componentName, config.ConfigTypeLogLevel)
+		configs := make([]map[string]string, len(componentList))
+		errs := make([]error, len(componentList))
+		forEachConcurrently(len(componentList), defaultConfigWorkers, func(i int) {
+			logConfig := logLevelComponentConfig(cm, model.LogLevel{ComponentName: componentList[i], DeviceId: options.Device, InstanceId: options.Instance})
+			configs[i], errs[i] = logConfig.RetrieveAll(ctx)
+		})
 
-		logLevelConfig, err = logConfig.RetrieveAll(context.Background())
-		if err != nil {
-			return fmt.Errorf("Unable to retrieve loglevel configuration for component %s : %s", componentName, err)
+		for i, componentName := range componentList {
+			if errs[i] != nil {
+				return fmt.Errorf("Unable to retrieve loglevel configuration for component %s : %s", componentName, describeConfigError(errs[i]))
+			}
+			scopedConfigs = append(scopedConfigs, config.ScopedConfig{
+				ComponentName: componentName,
+				DeviceId:      options.Device,
+				InstanceId:    options.Instance,
+				Entries:       configs[i],
+			})
 		}
+	}
 
-		for packageName, level := range logLevelConfig {
+	for _, scopedConfig := range scopedConfigs {
+		for packageName, level := range scopedConfig.Entries {
 			logLevel := model.LogLevel{}
 			if packageName == "" {
-				delete(logLevelConfig, packageName)
 				continue
 			}
 
-			pName := strings.ReplaceAll(packageName, "#", "/")
-			logLevel.PopulateFrom(componentName, pName, level)
+			logLevel.PopulateFrom(scopedConfig.ComponentName, packageName, level)
+			logLevel.DeviceId = scopedConfig.DeviceId
+			logLevel.InstanceId = scopedConfig.InstanceId
+			logLevel.Valid = isValidLogLevel(level)
+			if !logLevel.Valid {
+				Warn.Printf("Component %s package %s has invalid log level %q", scopedConfig.ComponentName, packageName, level)
+			}
+			scoped = scoped || logLevel.DeviceId != "" || logLevel.InstanceId != ""
 			data = append(data, logLevel)
 		}
 	}
 
 	outputFormat := CharReplacer.Replace(options.Format)
 	if outputFormat == "" {
-		outputFormat = GetCommandOptionWithDefault("loglevel-list", "format", DEFAULT_LOGLEVELS_FORMAT)
+		defaultFormat := DEFAULT_LOGLEVELS_FORMAT
+		if options.Device != "" {
+			defaultFormat = DEFAULT_DEVICE_LOGLEVELS_FORMAT
+		}
+		if options.Instance != "" {
+			defaultFormat = DEFAULT_INSTANCE_LOGLEVELS_FORMAT
+		}
+		if options.Device == "" && options.Instance == "" && scoped {
+			defaultFormat = DEFAULT_SCOPED_LOGLEVELS_FORMAT
+		}
+		if options.Effective {
+			defaultFormat += EFFECTIVE_LOGLEVELS_FORMAT_SUFFIX
+		}
+		outputFormat = GetCommandOptionWithDefault("loglevel-list", "format", defaultFormat)
 	}
 	orderBy := options.OrderBy
 	if orderBy == "" {
@@ -258,11 +892,48 @@ func (options *ListLogLevelsOpts) Execute(args []string) error {
 	return nil
 }
 
+// effectiveLogLevels resolves the loglevels in effect for the given components, or for all components with
+// loglevel config if none is given, the same way the LogLevelApplier of the components does
+func effectiveLogLevels(ctx context.Context, cm *config.ConfigManager, components []string, deviceId string, instanceId string) ([]model.LogLevel, error) {
+	var err error
+	if len(components) == 0 {
+		components, err = cm.RetrieveComponentList(ctx, config.ConfigTypeLogLevel)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve list of voltha components : %s ", describeConfigError(err))
+		}
+	}
+
+	resolved := make([]map[string]config.ResolvedValue, len(components))
+	errs := make([]error, len(components))
+	forEachConcurrently(len(components), defaultConfigWorkers, func(i int) {
+		logConfig := logLevelComponentConfig(cm, model.LogLevel{ComponentName: components[i], DeviceId: deviceId, InstanceId: instanceId})
+		resolved[i], errs[i] = logConfig.ResolveAll(ctx)
+	})
+
+	var data []model.LogLevel
+	for i, componentName := range components {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("Unable to resolve loglevel configuration for component %s : %s", componentName, describeConfigError(errs[i]))
+		}
+		for packageName, value := range resolved[i] {
+			logLevel := model.LogLevel{DeviceId: deviceId, InstanceId: instanceId, Source: value.Source.String(), Valid: true}
+			logLevel.PopulateFrom(componentName, packageName, value.Value)
+			data = append(data, logLevel)
+		}
+	}
+	return data, nil
+}
+
 // This method clear loglevel for components.
 // For example, using below command loglevel can be clear for specific component with default packageName
 // voltctl loglevel clear  <componentName>
 // For example, using below command loglevel can be clear for specific component with specific packageName
 // voltctl loglevel clear <componentName#packageName>
+// For example, using below command loglevel set for a single device can be clear for the component
+// voltctl loglevel clear <componentName#packageName> --device <deviceId>
+// For example, using below command loglevel set for a single instance can be clear for the component
+// voltctl loglevel clear <componentName#packageName> --instance <podName>
+// It uses the same exit codes as loglevel set
 func (options *ClearLogLevelsOpts) Execute(args []string) error {
 
 	var (
@@ -273,47 +944,37 @@ func (options *ClearLogLevelsOpts) Execute(args []string) error {
 	if len(options.Args.Component) == 0 {
 		var component []string
 		component = append(component, defaultComponentName)
-		logLevelConfig, err = processCommandArgs(component)
+		logLevelConfig, err = processCommandArgs(component, options.Device, options.Instance)
 	} else {
-		logLevelConfig, err = processCommandArgs(options.Args.Component)
+		logLevelConfig, err = processCommandArgs(options.Args.Component, options.Device, options.Instance)
 	}
 
 	if err != nil {
-		return fmt.Errorf("%s", err)
+		return generateFailedOutput(options.OutputOptions, "loglevel-clear", options.Args.Component, ExitCodeValidationFailure, err)
 	}
 
-	client, err := kvstore.NewEtcdClient(defaultKVStoreHost+":"+strconv.Itoa(defaultKVStorePort), defaultKVStoreTimeout)
-	defer client.Close()
+	cmOptions, err := options.configManagerOptions()
 	if err != nil {
-		return fmt.Errorf("Unable to create client %s", err)
+		return generateFailedOutput(options.OutputOptions, "loglevel-clear", options.Args.Component, ExitCodeValidationFailure, err)
 	}
-	cm := config.NewConfigManager(client, defaultKVStoreType, defaultKVStoreHost, defaultKVStorePort, defaultKVStoreTimeout)
 
-	var output []LogLevelOutput
-	for _, lConfig := range logLevelConfig {
+	ctx := context.Background()
+	cm, client, err := connectConfigManager(ctx, cmOptions...)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-clear", options.Args.Component, ExitCodeConnectionFailure, err)
+	}
+	defer client.Close()
 
-		logConfig := cm.InitComponentConfig(lConfig.ComponentName, config.ConfigTypeLogLevel)
+	output := make([]LogLevelOutput, len(logLevelConfig))
+	forEachConcurrently(len(logLevelConfig), defaultConfigWorkers, func(i int) {
+		lConfig := logLevelConfig[i]
+		logConfig := logLevelComponentConfig(cm, lConfig)
 
-		err := logConfig.Delete(context.Background(), lConfig.PackageName)
-		if err != nil {
-			output = append(output, LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Failure", Error: err.Error()})
+		if err := logConfig.Delete(ctx, lConfig.PackageName); err != nil {
+			output[i] = LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Failure", Error: describeConfigError(err), ErrorCode: configErrorCode(err)}
 		} else {
-			output = append(output, LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Success"})
+			output[i] = LogLevelOutput{ComponentName: lConfig.ComponentName, Status: "Success"}
 		}
-	}
-
-	outputFormat := CharReplacer.Replace(options.Format)
-	if outputFormat == "" {
-		outputFormat = GetCommandOptionWithDefault("loglevel-clear", "format", DEFAULT_LOGLEVEL_RESULT_FORMAT)
-	}
-
-	result := CommandResult{
-		Format:    format.Format(outputFormat),
-		OutputAs:  toOutputType(options.OutputAs),
-		NameLimit: options.NameLimit,
-		Data:      output,
-	}
-
-	GenerateOutput(&result)
-	return nil
+	})
+	return generateResultOutput(options.OutputOptions, "loglevel-clear", output)
 }
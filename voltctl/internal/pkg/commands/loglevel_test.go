@@ -0,0 +1,288 @@
+/*
+ * Copyright 2019-present Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/opencord/voltctl/pkg/model"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitComponentArg(t *testing.T) {
+	tests := []struct {
+		arg         string
+		component   string
+		packageName string
+		hasPackage  bool
+		fails       bool
+	}{
+		{arg: "rw-core", component: "rw-core"},
+		{arg: "", component: ""},
+		{arg: "rw-core#", component: "rw-core", packageName: "", hasPackage: true},
+		{arg: "#pkg", component: "", packageName: "pkg", hasPackage: true},
+		{arg: "rw-core#github.com/opencord/voltha-go/rw_core/core", component: "rw-core",
+			packageName: "github.com/opencord/voltha-go/rw_core/core", hasPackage: true},
+		{arg: `rw-core#pkg\#1`, component: "rw-core", packageName: "pkg#1", hasPackage: true},
+		{arg: `rw\#core#pkg`, component: "rw#core", packageName: "pkg", hasPackage: true},
+		{arg: `rw-core#pkg\\`, component: "rw-core", packageName: `pkg\`, hasPackage: true},
+		{arg: "rw-core#100%", component: "rw-core", packageName: "100%", hasPackage: true},
+		{arg: "rw-core#paquet/événement", component: "rw-core", packageName: "paquet/événement", hasPackage: true},
+		{arg: "rw-core#a#b", fails: true},
+		{arg: `rw-core#pkg\`, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			component, packageName, hasPackage, err := splitComponentArg(tt.arg)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("splitComponentArg(%q) succeeded, expected an error", tt.arg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitComponentArg(%q) failed: %v", tt.arg, err)
+			}
+			if component != tt.component || packageName != tt.packageName || hasPackage != tt.hasPackage {
+				t.Errorf("splitComponentArg(%q) = %q, %q, %v, expected %q, %q, %v", tt.arg,
+					component, packageName, hasPackage, tt.component, tt.packageName, tt.hasPackage)
+			}
+
+			// The names are encoded into a single kvstore path element each and decoded back unchanged
+			for _, name := range []string{component, packageName} {
+				if decoded := config.DecodeConfigKey(config.EncodeConfigKey(name)); decoded != name {
+					t.Errorf("%q is decoded as %q", name, decoded)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeLogLevel(t *testing.T) {
+	tests := []struct {
+		level      string
+		normalized string
+		fails      bool
+	}{
+		{level: "debug", normalized: "DEBUG"},
+		{level: " Warn ", normalized: "WARN"},
+		{level: "0", normalized: "DEBUG"},
+		{level: "1", normalized: "INFO"},
+		{level: "2", normalized: "WARN"},
+		{level: "3", normalized: "ERROR"},
+		{level: "4", normalized: "FATAL"},
+		{level: "5", fails: true},
+		{level: "-1", fails: true},
+		{level: "verbose", fails: true},
+		{level: "", fails: true},
+	}
+	for _, tt := range tests {
+		normalized, err := normalizeLogLevel(tt.level)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("normalizeLogLevel(%q) = %q, expected an error", tt.level, normalized)
+			}
+			continue
+		}
+		if err != nil || normalized != tt.normalized {
+			t.Errorf("normalizeLogLevel(%q) = %q, %v, expected %q", tt.level, normalized, err, tt.normalized)
+		}
+	}
+}
+
+func TestSupportedLogLevels(t *testing.T) {
+	levels := supportedLogLevels()
+	if len(levels) == 0 || levels[0] != log.DebugLevel {
+		t.Fatalf("supportedLogLevels returned %v, expected the levels from DEBUG up", levels)
+	}
+	// Every level of the log library is accepted by name and by number
+	for _, l := range levels {
+		name, err := log.LogLevelToString(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, arg := range []string{strings.ToLower(name), strconv.Itoa(int(l))} {
+			if normalized, err := normalizeLogLevel(arg); err != nil || normalized != name {
+				t.Errorf("normalizeLogLevel(%q) = %q, %v, expected %q", arg, normalized, err, name)
+			}
+		}
+	}
+
+	_, err := normalizeLogLevel("verbose")
+	if err == nil || !strings.Contains(err.Error(), "FATAL(4)") {
+		t.Errorf("normalizeLogLevel returned %v, expected the allowed levels with their numbers", err)
+	}
+}
+
+func TestConfigErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"etcd unavailable", status.Error(codes.Unavailable, "etcdserver: no leader"), ExitCodeConnectionFailure},
+		{"wrapped unavailable", &config.Error{Kind: config.ErrUnavailable, Operation: "put", Err: errors.New("connection refused")}, ExitCodeConnectionFailure},
+		{"deadline", context.DeadlineExceeded, ExitCodeConnectionFailure},
+		{"wrapped deadline", fmt.Errorf("put failed: %w", context.DeadlineExceeded), ExitCodeConnectionFailure},
+		{"invalid value", &config.Error{Kind: config.ErrInvalidValue, Operation: "validate"}, ExitCodeValidationFailure},
+		{"invalid argument", status.Error(codes.InvalidArgument, "bad key"), ExitCodeValidationFailure},
+		{"canceled", context.Canceled, ExitCodeFailure},
+		{"other", errors.New("unexpected"), ExitCodeFailure},
+	}
+	for _, tt := range tests {
+		if code := configErrorCode(tt.err); code != tt.code {
+			t.Errorf("%s: configErrorCode returned %d, expected %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestConfigExitCode(t *testing.T) {
+	success := LogLevelOutput{Status: "Success"}
+	connection := LogLevelOutput{Status: "Failure", ErrorCode: ExitCodeConnectionFailure}
+	validation := LogLevelOutput{Status: "Failure", ErrorCode: ExitCodeValidationFailure}
+	tests := []struct {
+		name   string
+		output []LogLevelOutput
+		code   int
+	}{
+		{"all succeeded", []LogLevelOutput{success, success}, ExitCodeSuccess},
+		{"some failed", []LogLevelOutput{success, connection}, ExitCodePartialFailure},
+		{"all failed alike", []LogLevelOutput{connection, connection}, ExitCodeConnectionFailure},
+		{"all failed differently", []LogLevelOutput{connection, validation}, ExitCodeFailure},
+	}
+	for _, tt := range tests {
+		if code := configExitCode(tt.output); code != tt.code {
+			t.Errorf("%s: configExitCode returned %d, expected %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestGenerateFailedOutput(t *testing.T) {
+	cause := errors.New("Unable to connect to kvstore")
+	err := generateFailedOutput(OutputOptions{}, "loglevel-set", nil, ExitCodeConnectionFailure, cause)
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("generateFailedOutput returned %v, expected an ExitError", err)
+	}
+	if exitErr.Code != ExitCodeConnectionFailure || !errors.Is(err, cause) {
+		t.Errorf("generateFailedOutput returned code %d with %v", exitErr.Code, exitErr.Err)
+	}
+
+	if err := generateResultOutput(OutputOptions{}, "loglevel-set", []LogLevelOutput{{Status: "Success"}}); err != nil {
+		t.Errorf("generateResultOutput returned %v for a successful result", err)
+	}
+}
+
+func TestExitOnError(t *testing.T) {
+	if os.Getenv("VOLTCTL_TEST_EXIT_ON_ERROR") != "" {
+		ExitOnError(fmt.Errorf("loglevel set: %w", exitWithCode(ExitCodePartialFailure, nil)))
+		return
+	}
+
+	// Other errors are left to the caller
+	ExitOnError(nil)
+	ExitOnError(errors.New("unknown flag"))
+
+	// The test binary runs itself to exit with the code of the ExitError
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExitOnError$")
+	cmd.Env = append(os.Environ(), "VOLTCTL_TEST_EXIT_ON_ERROR=1")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != ExitCodePartialFailure {
+		t.Errorf("the process ended with %v, expected exit code %d", err, ExitCodePartialFailure)
+	}
+}
+
+func TestExpectedAcknowledgements(t *testing.T) {
+	acks := map[string]map[string]config.Acknowledgement{
+		"rw-core":          {"rw-core-0": {}},
+		"adapter-open-olt": {"olt-0": {}},
+		"ofagent":          {"ofagent-0": {}},
+	}
+	ownDefaults := map[string]bool{"rw-core": true}
+
+	components := expectedAcknowledgements(model.LogLevel{ComponentName: "ofagent"}, ownDefaults, acks)
+	if !reflect.DeepEqual(components, []string{"ofagent"}) {
+		t.Errorf("a component level is expected from %v", components)
+	}
+	// The global default is not acknowledged by components with a default level of their own
+	components = expectedAcknowledgements(model.LogLevel{ComponentName: defaultComponentName}, ownDefaults, acks)
+	if !reflect.DeepEqual(components, []string{"adapter-open-olt", "ofagent"}) {
+		t.Errorf("the global level is expected from %v", components)
+	}
+}
+
+func TestLaggingInstances(t *testing.T) {
+	now := time.Now()
+	applied := func(level string, version int64) config.Acknowledgement {
+		return config.Acknowledgement{
+			Applied:   map[string]string{"default": level},
+			Origins:   map[string]config.ConfigOrigin{"default": {Source: config.ConfigSourceComponent, Version: version}},
+			Timestamp: now.Add(-time.Hour),
+		}
+	}
+	acks := map[string]map[string]config.Acknowledgement{
+		"rw-core": {"rw-core-0": applied("DEBUG", 3), "rw-core-1": applied("DEBUG", 2), "rw-core-2": applied("INFO", 3)},
+	}
+	for component, instances := range acks {
+		for instance, ack := range instances {
+			ack.Instance = instance
+			acks[component][instance] = ack
+		}
+	}
+	// The acknowledgements are an hour old by the clock of voltctl, but they are refreshed
+	liveness := config.NewLivenessTracker()
+	liveness.Observe(now, acks)
+
+	target := acknowledgementTarget{source: config.ConfigSourceComponent, version: 3}
+	lagging, alive := laggingInstances([]string{"rw-core"}, "", "default", "DEBUG", target, acks, liveness, now)
+	if !alive || !reflect.DeepEqual(lagging, []string{"rw-core/rw-core-1", "rw-core/rw-core-2"}) {
+		t.Errorf("laggingInstances returned %v, %v", lagging, alive)
+	}
+	lagging, alive = laggingInstances([]string{"rw-core"}, "rw-core-0", "default", "DEBUG", target, acks, liveness, now)
+	if !alive || len(lagging) != 0 {
+		t.Errorf("laggingInstances returned %v, %v for an acknowledging instance", lagging, alive)
+	}
+
+	// Instances that stopped refreshing their acknowledgement are not waited for
+	lagging, alive = laggingInstances([]string{"rw-core"}, "", "default", "DEBUG", target, acks, liveness, now.Add(time.Hour))
+	if alive || len(lagging) != 0 {
+		t.Errorf("laggingInstances returned %v, %v for stopped instances", lagging, alive)
+	}
+}
+
+func TestRegisterLogLevelCommands(t *testing.T) {
+	parser := flags.NewParser(&struct{}{}, flags.Default)
+	RegisterLogLevelCommands(parser)
+	for _, name := range []string{"loglevel", "logformat", "tracing", "config"} {
+		if parser.Find(name) == nil {
+			t.Errorf("the %s command is not registered", name)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/opencord/voltctl/pkg/model"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+	"sort"
+)
+
+// SetLogSamplingOpts represents the supported CLI arguments for the loglevel sampling set command
+type SetLogSamplingOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Set the sampling for a single instance (pod) of the component only"`
+	Args     struct {
+		Sampling  string
+		Component []string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ListLogSamplingOpts represents the supported CLI arguments for the loglevel sampling list command
+type ListLogSamplingOpts struct {
+	ListOutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"List the sampling set for a single instance (pod) of the component"`
+	Args     struct {
+		Component []string
+	} `positional-args:"yes"`
+}
+
+// ClearLogSamplingOpts represents the supported CLI arguments for the loglevel sampling clear command
+type ClearLogSamplingOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Clear the sampling set for a single instance (pod) of the component"`
+	Args     struct {
+		Component []string
+	} `positional-args:"yes"`
+}
+
+// LogSamplingOpts represents the loglevel sampling commands
+type LogSamplingOpts struct {
+	SetLogSampling   SetLogSamplingOpts   `command:"set"`
+	ListLogSampling  ListLogSamplingOpts  `command:"list"`
+	ClearLogSampling ClearLogSamplingOpts `command:"clear"`
+}
+
+const (
+	DEFAULT_LOGSAMPLING_FORMAT          = "table{{ .ComponentName }}\t{{.PackageName}}\t{{.Sampling}}"
+	DEFAULT_INSTANCE_LOGSAMPLING_FORMAT = "table{{ .ComponentName }}\t{{.InstanceId}}\t{{.PackageName}}\t{{.Sampling}}"
+)
+
+// logSamplingTargets returns the targets for the <componentName>[#<packageName>] arguments, the global
+// default if none is given. The key of a target is the package, or the default package if the argument
+// names none
+func logSamplingTargets(components []string, instanceId string) ([]configTarget, error) {
+	if len(components) == 0 {
+		components = []string{defaultComponentName}
+	}
+
+	targets := make([]configTarget, len(components))
+	for i, component := range components {
+		logLevelConfig, err := processCommandArgs([]string{component}, "", instanceId)
+		if err != nil {
+			return nil, err
+		}
+		_, _, hasPackage, _ := splitComponentArg(component)
+		lConfig := logLevelConfig[0]
+		targets[i] = configTarget{componentName: lConfig.ComponentName, instanceId: lConfig.InstanceId, key: lConfig.PackageName, keyGiven: hasPackage}
+	}
+	return targets, nil
+}
+
+// logSamplingFilter returns the packages to list by component for the <componentName>[#<packageName>]
+// arguments, which are checked as those of set and clear. A component given without a package lists all
+// of its packages, which is marked by a nil set. A single instance can only be listed for named components
+func logSamplingFilter(components []string, instanceId string) (map[string]map[string]bool, error) {
+	if len(components) == 0 && instanceId != "" {
+		return nil, errors.New("The component name is required with --instance")
+	}
+	targets, err := logSamplingTargets(components, instanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := make(map[string]map[string]bool)
+	for _, target := range targets {
+		packages, listed := filter[target.componentName]
+		if listed && packages == nil {
+			continue
+		}
+		if !target.keyGiven {
+			filter[target.componentName] = nil
+			continue
+		}
+		if packages == nil {
+			packages = make(map[string]bool)
+			filter[target.componentName] = packages
+		}
+		packages[target.key] = true
+	}
+	return filter, nil
+}
+
+// This method sets the sampling of components and packages. Every second the first <initial> entries
+// with the same message are logged and then every <thereafter>-th.
+// For example, using below command entries of all components are sampled
+// voltctl loglevel sampling set 100/10
+// For example, using below command entries of a specific package of a component are sampled
+// voltctl loglevel sampling set 100/100 <componentName#packageName>
+// For example, using below command entries of a single instance of a component are sampled
+// voltctl loglevel sampling set 10/100 <componentName> --instance <podName>
+// An invalid sampling is refused before any component is updated.
+func (options *SetLogSamplingOpts) Execute(args []string) error {
+	sampling, err := config.ParseLogSampling(options.Args.Sampling)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-sampling-set", options.Args.Component, ExitCodeValidationFailure, err)
+	}
+	targets, err := logSamplingTargets(options.Args.Component, options.Instance)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-sampling-set", options.Args.Component, ExitCodeValidationFailure, err)
+	}
+
+	return updateComponentConfigs(options.OutputOptions, options.KvStoreOptions, "loglevel-sampling-set", config.ConfigTypeLogSampling, targets,
+		func(ctx context.Context, componentConfig *config.ComponentConfig, key string) error {
+			return componentConfig.Save(ctx, key, sampling.String())
+		})
+}
+
+// This method clears the sampling of components and packages, whose entries are then sampled as the
+// component or global default, or not at all.
+// For example, using below command the sampling of a specific package of a component is cleared
+// voltctl loglevel sampling clear <componentName#packageName>
+// For example, using below command the sampling of all packages of a component is cleared
+// voltctl loglevel sampling clear <componentName>
+func (options *ClearLogSamplingOpts) Execute(args []string) error {
+	targets, err := logSamplingTargets(options.Args.Component, options.Instance)
+	if err != nil {
+		return generateFailedOutput(options.OutputOptions, "loglevel-sampling-clear", options.Args.Component, ExitCodeValidationFailure, err)
+	}
+
+	return updateComponentConfigs(options.OutputOptions, options.KvStoreOptions, "loglevel-sampling-clear", config.ConfigTypeLogSampling, targets,
+		func(ctx context.Context, componentConfig *config.ComponentConfig, key string) error {
+			return componentConfig.Delete(ctx, key)
+		})
+}
+
+// This method lists the sampling set for components. Components are given as for set and clear.
+// For example, using below command the sampling of all components is listed
+// voltctl loglevel sampling list
+// For example, using below command the sampling of a specific package of a component is listed
+// voltctl loglevel sampling list <componentName#packageName>
+// For example, using below command the sampling set for a single instance is listed for the component
+// voltctl loglevel sampling list <componentName> --instance <podName>
+func (options *ListLogSamplingOpts) Execute(args []string) error {
+	var (
+		components []string
+		filter     map[string]map[string]bool
+		err        error
+	)
+
+	if len(options.Args.Component) > 0 || options.Instance != "" {
+		if filter, err = logSamplingFilter(options.Args.Component, options.Instance); err != nil {
+			return err
+		}
+		for componentName := range filter {
+			components = append(components, componentName)
+		}
+		sort.Strings(components)
+	}
+
+	return listComponentConfigs(options.ListOutputOptions, options.KvStoreOptions, configListing{
+		commandName:    "loglevel-sampling-list",
+		configType:     config.ConfigTypeLogSampling,
+		format:         DEFAULT_LOGSAMPLING_FORMAT,
+		instanceFormat: DEFAULT_INSTANCE_LOGSAMPLING_FORMAT,
+		model:          model.LogSampling{},
+		row: func(componentName string, instanceId string, packageName string, sampling string) (interface{}, bool) {
+			if packages := filter[componentName]; packages != nil && !packages[packageName] {
+				return nil, false
+			}
+			logSampling := model.LogSampling{InstanceId: instanceId}
+			logSampling.PopulateFrom(componentName, packageName, sampling)
+			_, err := config.ParseLogSampling(sampling)
+			logSampling.Valid = err == nil
+			if !logSampling.Valid {
+				Warn.Printf("Component %s package %s has invalid sampling %q", componentName, packageName, sampling)
+			}
+			return logSampling, true
+		},
+	}, components, options.Instance)
+}
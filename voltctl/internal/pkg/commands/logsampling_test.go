@@ -0,0 +1,71 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogSamplingArguments(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []string
+		instanceId string
+		filter     map[string]map[string]bool
+		fails      bool
+	}{
+		{name: "global", components: nil, filter: map[string]map[string]bool{"global": nil}},
+		{name: "component", components: []string{"rw-core"}, filter: map[string]map[string]bool{"rw-core": nil}},
+		{name: "packages", components: []string{"rw-core#pkg/a", "rw-core#pkg/b", "ofagent"},
+			filter: map[string]map[string]bool{"rw-core": {"pkg/a": true, "pkg/b": true}, "ofagent": nil}},
+		{name: "default package", components: []string{"rw-core#default"}, filter: map[string]map[string]bool{"rw-core": {"default": true}}},
+		{name: "component and package", components: []string{"rw-core#pkg/a", "rw-core"}, filter: map[string]map[string]bool{"rw-core": nil}},
+		{name: "instance", components: []string{"rw-core#pkg/a"}, instanceId: "rw-core-0", filter: map[string]map[string]bool{"rw-core": {"pkg/a": true}}},
+		{name: "global package", components: []string{"global#pkg/a"}, fails: true},
+		{name: "instance without component", instanceId: "rw-core-0", fails: true},
+		{name: "missing package", components: []string{"rw-core#"}, fails: true},
+		{name: "two separators", components: []string{"rw-core#a#b"}, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := logSamplingFilter(tt.components, tt.instanceId)
+			// list accepts the same arguments as set and clear
+			_, targetsErr := logSamplingTargets(tt.components, tt.instanceId)
+			if (err == nil) != (targetsErr == nil) {
+				t.Fatalf("list returned %v, set and clear %v", err, targetsErr)
+			}
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("logSamplingFilter returned %v, expected an error", filter)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(filter, tt.filter) {
+				t.Errorf("logSamplingFilter returned %v, expected %v", filter, tt.filter)
+			}
+		})
+	}
+
+	if _, err := logSamplingFilter(nil, "rw-core-0"); err == nil || !strings.Contains(err.Error(), "component name is required") {
+		t.Errorf("logSamplingFilter returned %v for an instance without component", err)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package commands
+
+import (
+	flags "github.com/jessevdk/go-flags"
+	"github.com/opencord/voltctl/pkg/model"
+	"github.com/opencord/voltha-lib-go/v3/pkg/config"
+)
+
+// SetTracingOpts represents the supported CLI arguments for the tracing set command
+type SetTracingOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Set the tracing config for a single instance (pod) of the component only"`
+	Args     struct {
+		Key       string
+		Value     string
+		Component []string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ListTracingOpts represents the supported CLI arguments for the tracing list command
+type ListTracingOpts struct {
+	ListOutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"List the tracing config set for a single instance (pod) of the component"`
+	Args     struct {
+		Component []string
+	} `positional-args:"yes"`
+}
+
+// ClearTracingOpts represents the supported CLI arguments for the tracing clear command
+type ClearTracingOpts struct {
+	OutputOptions
+	KvStoreOptions
+	Instance string `long:"instance" value-name:"INSTANCE_ID" description:"Clear the tracing config set for a single instance (pod) of the component"`
+	Args     struct {
+		Key       string
+		Component []string
+	} `positional-args:"yes" required:"yes"`
+}
+
+// TracingOpts represents the tracing commands
+type TracingOpts struct {
+	SetTracing   SetTracingOpts   `command:"set"`
+	ListTracing  ListTracingOpts  `command:"list"`
+	ClearTracing ClearTracingOpts `command:"clear"`
+}
+
+var tracingOpts = TracingOpts{}
+
+// RegisterTracingCommands is used to register set, list and clear tracing config of components
+func RegisterTracingCommands(parent *flags.Parser) {
+	_, err := parent.AddCommand("tracing", "tracing commands", "list, set and clear the distributed tracing config of components", &tracingOpts)
+	if err != nil {
+		Error.Fatalf("Unable to register tracing commands with voltctl command parser: %s", err.Error())
+	}
+}
+
+// This method sets a tracing key for components.
+// The keys are enabled (true or false), sampling-ratio (0 to 1) and collector (host:port).
+// For example, using below command span export can be enabled for all components
+// voltctl tracing set enabled true
+// For example, using below command a tenth of the traces of specific components are sampled
+// voltctl tracing set sampling-ratio 0.1 <componentName1> <componentName2>
+// For example, using below command spans of a single instance of a component are sent to a local collector
+// voltctl tracing set collector localhost:6831 <componentName> --instance <podName>
+func (options *SetTracingOpts) Execute(args []string) error {
+	return saveComponentConfigs(options.OutputOptions, options.KvStoreOptions, "tracing-set", config.ConfigTypeTracing,
+		options.Args.Component, options.Instance, options.Args.Key, options.Args.Value)
+}
+
+// This method clears a tracing key for components, which then fall back to the global or built-in config.
+// Clearing enabled of a component does not stop tracing if it is enabled globally.
+// For example, using below command the sampling ratio set for a specific component can be cleared
+// voltctl tracing clear sampling-ratio <componentName>
+// For example, using below command the collector set for a single instance of a component can be cleared
+// voltctl tracing clear collector <componentName> --instance <podName>
+func (options *ClearTracingOpts) Execute(args []string) error {
+	return deleteComponentConfigs(options.OutputOptions, options.KvStoreOptions, "tracing-clear", config.ConfigTypeTracing,
+		config.TracingKeys(), options.Args.Component, options.Instance, options.Args.Key)
+}
+
+// This method lists the tracing keys set for components, without the built-in defaults.
+// For example, using below command the tracing config of all components can be list
+// voltctl tracing list
+// For example, using below command the tracing config set for a single instance can be list for the component
+// voltctl tracing list <componentName> --instance <podName>
+func (options *ListTracingOpts) Execute(args []string) error {
+	components, err := listedComponentNames(options.Args.Component, options.Instance)
+	if err != nil {
+		return err
+	}
+
+	return listComponentConfigs(options.ListOutputOptions, options.KvStoreOptions, configListing{
+		commandName:    "tracing-list",
+		configType:     config.ConfigTypeTracing,
+		format:         DEFAULT_COMPONENT_CONFIG_FORMAT,
+		instanceFormat: DEFAULT_INSTANCE_COMPONENT_CONFIG_FORMAT,
+		model:          model.Tracing{},
+		row: func(componentName string, instanceId string, key string, value string) (interface{}, bool) {
+			tracing := model.Tracing{InstanceId: instanceId}
+			tracing.PopulateFrom(componentName, key, value)
+			return tracing, true
+		},
+	}, components, options.Instance)
+}
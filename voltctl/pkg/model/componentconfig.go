@@ -0,0 +1,40 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+type ComponentConfig struct {
+	ComponentName string
+	InstanceId    string
+	ConfigType    string
+	Key           string
+	Value         string
+}
+
+func (componentConfig *ComponentConfig) PopulateFrom(componentName, configType, key, value string) {
+	componentConfig.ComponentName = componentName
+	componentConfig.ConfigType = configType
+	componentConfig.Key = key
+	componentConfig.Value = value
+}
+
+type ConfigChange struct {
+	ComponentName string
+	InstanceId    string
+	ConfigType    string
+	ChangeType    string
+	Key           string
+	Value         string
+}
@@ -17,8 +17,12 @@ package model
 
 type LogLevel struct {
 	ComponentName string
+	DeviceId      string
+	InstanceId    string
 	PackageName   string
 	Level         string
+	Valid         bool
+	Source        string
 }
 
 func (logLevel *LogLevel) PopulateFrom(componentName,packageName,level string) {